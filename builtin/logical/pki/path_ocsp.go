@@ -0,0 +1,391 @@
+package pki
+
+import (
+	"context"
+	"crypto"
+	"crypto/subtle"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"golang.org/x/crypto/ocsp"
+)
+
+const (
+	ocspUnknownNextUpdate = time.Hour
+	ocspDefaultNextUpdate = 24 * time.Hour
+)
+
+// externalCrlRevocation is one revoked serial most recently aggregated by
+// issuer/<ref>/resign-crls for a given issuer, persisted under its own
+// storage entry (keyed by serial) so the OCSP responder can look a serial
+// up with a single Storage.Get instead of holding every resigned CRL's
+// revocation set in one entry, which would run into Vault's per-entry
+// storage size limit for any sizeable input.
+type externalCrlRevocation struct {
+	RevokedAtUTC time.Time `json:"revoked_at_utc"`
+}
+
+// externalCrlRevocationsMeta carries the next_update the most recent
+// resign-crls call for an issuer requested, applied to every serial in its
+// aggregated set.
+type externalCrlRevocationsMeta struct {
+	NextUpdateSecs int `json:"next_update_seconds"`
+}
+
+func externalCrlRevocationsMetaStoragePath(issuerId issuerID) string {
+	return "external-crl-revocations/" + issuerId.String() + "/meta"
+}
+
+func externalCrlRevocationsSerialsPrefix(issuerId issuerID) string {
+	return "external-crl-revocations/" + issuerId.String() + "/serials/"
+}
+
+func externalCrlRevocationStoragePath(issuerId issuerID, serial string) string {
+	return externalCrlRevocationsSerialsPrefix(issuerId) + serial
+}
+
+// storeExternalCrlRevocations replaces the aggregated revocation set for an
+// issuer: it deletes whatever serials were stored by a prior resign-crls
+// call and writes one storage entry per serial in revokedAtBySerial, each
+// holding that serial's actual revocation time.
+func storeExternalCrlRevocations(ctx context.Context, sc *storageContext, issuerId issuerID, revokedAtBySerial map[string]time.Time, nextUpdateSeconds int) error {
+	existing, err := sc.Storage.List(ctx, externalCrlRevocationsSerialsPrefix(issuerId))
+	if err != nil {
+		return fmt.Errorf("failed listing prior external CRL revocation set: %w", err)
+	}
+	for _, serial := range existing {
+		if err := sc.Storage.Delete(ctx, externalCrlRevocationsSerialsPrefix(issuerId)+serial); err != nil {
+			return fmt.Errorf("failed clearing prior external CRL revocation entry for serial %s: %w", serial, err)
+		}
+	}
+
+	for serial, revokedAt := range revokedAtBySerial {
+		entry, err := logical.StorageEntryJSON(externalCrlRevocationStoragePath(issuerId, serial), externalCrlRevocation{
+			RevokedAtUTC: revokedAt.UTC(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed encoding external CRL revocation entry for serial %s: %w", serial, err)
+		}
+		if err := sc.Storage.Put(ctx, entry); err != nil {
+			return err
+		}
+	}
+
+	meta, err := logical.StorageEntryJSON(externalCrlRevocationsMetaStoragePath(issuerId), externalCrlRevocationsMeta{
+		NextUpdateSecs: nextUpdateSeconds,
+	})
+	if err != nil {
+		return fmt.Errorf("failed encoding external CRL revocation metadata: %w", err)
+	}
+
+	return sc.Storage.Put(ctx, meta)
+}
+
+func fetchExternalCrlRevocation(ctx context.Context, sc *storageContext, issuerId issuerID, serial string) (*externalCrlRevocation, error) {
+	raw, err := sc.Storage.Get(ctx, externalCrlRevocationStoragePath(issuerId, serial))
+	if err != nil {
+		return nil, err
+	}
+	if raw == nil {
+		return nil, nil
+	}
+
+	var out externalCrlRevocation
+	if err := json.Unmarshal(raw.Value, &out); err != nil {
+		return nil, fmt.Errorf("failed decoding external CRL revocation entry for serial %s: %w", serial, err)
+	}
+
+	return &out, nil
+}
+
+func fetchExternalCrlRevocationsMeta(ctx context.Context, sc *storageContext, issuerId issuerID) (*externalCrlRevocationsMeta, error) {
+	raw, err := sc.Storage.Get(ctx, externalCrlRevocationsMetaStoragePath(issuerId))
+	if err != nil {
+		return nil, err
+	}
+	if raw == nil {
+		return nil, nil
+	}
+
+	var out externalCrlRevocationsMeta
+	if err := json.Unmarshal(raw.Value, &out); err != nil {
+		return nil, fmt.Errorf("failed decoding external CRL revocation metadata: %w", err)
+	}
+
+	return &out, nil
+}
+
+func pathOcspGet(b *backend) *framework.Path {
+	return &framework.Path{
+		// Standard base64 (RFC 6960 Appendix A) legitimately contains '/',
+		// '+', and '=', none of which framework.GenericNameRegex's \w/-/.
+		// character class allows, so a real OCSP GET request would 404
+		// before reaching the handler's own base64 fallback logic.
+		Pattern: `ocsp/` + framework.MatchAllRegex("req"),
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+			OperationSuffix: "ocsp-request",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"req": {
+				Type:        framework.TypeString,
+				Description: `base64 encoded OCSP request, per RFC 6960 Appendix A.`,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback:    b.pathOcspHandler,
+				Unpublished: true,
+			},
+		},
+
+		HelpSynopsis:    pathOcspHelpSyn,
+		HelpDescription: pathOcspHelpDesc,
+	}
+}
+
+func pathOcspPost(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: `ocsp`,
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+			OperationSuffix: "ocsp-request",
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback:    b.pathOcspHandler,
+				Unpublished: true,
+			},
+		},
+
+		HelpSynopsis:    pathOcspHelpSyn,
+		HelpDescription: pathOcspHelpDesc,
+	}
+}
+
+// pathOcspHandler always signs with the targeted issuer's own cert and key;
+// it does not verify an OCSP request's optional signature and has no notion
+// of a separate designated OCSP responder certificate. ocsp.ParseRequest
+// discards a signed request's optional signature, so it is never
+// inspected. See pathOcspHelpDesc.
+func (b *backend) pathOcspHandler(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	derReq, err := extractOcspRequestBytes(req, data)
+	if err != nil {
+		return malformedOcspResponse(), nil
+	}
+
+	ocspReq, err := ocsp.ParseRequest(derReq)
+	if err != nil {
+		return malformedOcspResponse(), nil
+	}
+
+	sc := b.makeStorageContext(ctx, req.Storage)
+	issuerId, issuerCert, signer, err := findOcspIssuer(sc, ocspReq)
+	if err != nil {
+		return unauthorizedOcspResponse(), nil
+	}
+
+	status, revokedAt, nextUpdate, err := lookupOcspStatus(ctx, sc, issuerId, ocspReq.SerialNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	template := ocsp.Response{
+		Status:       status,
+		SerialNumber: ocspReq.SerialNumber,
+		ThisUpdate:   time.Now(),
+		NextUpdate:   time.Now().Add(nextUpdate),
+		Certificate:  issuerCert,
+	}
+	if status == ocsp.Revoked {
+		template.RevokedAt = revokedAt
+		template.RevocationReason = ocsp.Unspecified
+	}
+
+	respBytes, err := ocsp.CreateResponse(issuerCert, issuerCert, template, signer)
+	if err != nil {
+		return nil, fmt.Errorf("failed signing OCSP response: %w", err)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			logical.HTTPStatusCode:  http.StatusOK,
+			logical.HTTPRawBody:     respBytes,
+			logical.HTTPContentType: "application/ocsp-response",
+		},
+	}, nil
+}
+
+func extractOcspRequestBytes(req *logical.Request, data *framework.FieldData) ([]byte, error) {
+	if req.Operation == logical.ReadOperation {
+		encoded := data.Get("req").(string)
+		if decoded, err := base64.StdEncoding.DecodeString(encoded); err == nil {
+			return decoded, nil
+		}
+		decoded, err := base64.URLEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("failed decoding base64 OCSP GET request: %w", err)
+		}
+		return decoded, nil
+	}
+
+	if len(req.HTTPRequestBody) == 0 {
+		return nil, fmt.Errorf("missing application/ocsp-request body")
+	}
+
+	return req.HTTPRequestBody, nil
+}
+
+// findOcspIssuer locates the issuer targeted by an OCSP request's
+// issuerNameHash/issuerKeyHash (RFC 6960 §4.1.1) among every issuer
+// configured on this mount.
+func findOcspIssuer(sc *storageContext, ocspReq *ocsp.Request) (issuerID, *x509.Certificate, crypto.Signer, error) {
+	ids, err := sc.listIssuers()
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	h := ocspReq.HashAlgorithm.New()
+	for _, id := range ids {
+		caInfo, err := sc.fetchCAInfoByIssuerId(id, OCSPUsage)
+		if err != nil {
+			continue
+		}
+
+		h.Reset()
+		h.Write(caInfo.Certificate.RawSubject)
+		nameHash := h.Sum(nil)
+
+		keyHash, err := hashSubjectPublicKey(caInfo.Certificate, ocspReq.HashAlgorithm)
+		if err != nil {
+			continue
+		}
+
+		if subtle.ConstantTimeCompare(nameHash, ocspReq.IssuerNameHash) == 1 &&
+			subtle.ConstantTimeCompare(keyHash, ocspReq.IssuerKeyHash) == 1 {
+			return id, caInfo.Certificate, caInfo.PrivateKey, nil
+		}
+	}
+
+	return "", nil, nil, fmt.Errorf("no configured issuer matches the OCSP request's issuerNameHash/issuerKeyHash")
+}
+
+// hashSubjectPublicKey hashes the subjectPublicKey BIT STRING content of a
+// certificate, the quantity RFC 6960 calls the issuerKeyHash, which is not
+// directly exposed by crypto/x509.
+func hashSubjectPublicKey(cert *x509.Certificate, alg crypto.Hash) ([]byte, error) {
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(cert.RawSubjectPublicKeyInfo, &spki); err != nil {
+		return nil, fmt.Errorf("failed parsing SubjectPublicKeyInfo: %w", err)
+	}
+
+	h := alg.New()
+	h.Write(spki.PublicKey.RightAlign())
+	return h.Sum(nil), nil
+}
+
+// lookupOcspStatus answers revoked or good for a serial against both the
+// externally resigned CRLs merged by resign-crls for the given issuer and
+// Vault's own revocation store. A serial found in neither is reported good;
+// ocsp.Unknown is returned only together with a non-nil storage error.
+func lookupOcspStatus(ctx context.Context, sc *storageContext, issuerId issuerID, serial *big.Int) (int, time.Time, time.Duration, error) {
+	serialString := serialFromBigInt(serial)
+
+	external, err := fetchExternalCrlRevocation(ctx, sc, issuerId, serialString)
+	if err != nil {
+		return ocsp.Unknown, time.Time{}, ocspUnknownNextUpdate, err
+	}
+	if external != nil {
+		nextUpdate := ocspDefaultNextUpdate
+		if meta, err := fetchExternalCrlRevocationsMeta(ctx, sc, issuerId); err != nil {
+			return ocsp.Unknown, time.Time{}, ocspUnknownNextUpdate, err
+		} else if meta != nil && meta.NextUpdateSecs > 0 {
+			nextUpdate = time.Duration(meta.NextUpdateSecs) * time.Second
+		}
+		return ocsp.Revoked, external.RevokedAtUTC, nextUpdate, nil
+	}
+
+	revEntry, err := fetchCertRevocationInfo(ctx, sc, serialString)
+	if err != nil {
+		return ocsp.Unknown, time.Time{}, ocspUnknownNextUpdate, err
+	}
+	if revEntry != nil {
+		return ocsp.Revoked, revEntry.RevocationTimeUTC, ocspDefaultNextUpdate, nil
+	}
+
+	return ocsp.Good, time.Time{}, ocspDefaultNextUpdate, nil
+}
+
+type certRevocationInfo struct {
+	RevocationTimeUTC time.Time `json:"revocation_time_utc"`
+}
+
+func fetchCertRevocationInfo(ctx context.Context, sc *storageContext, serial string) (*certRevocationInfo, error) {
+	raw, err := sc.Storage.Get(ctx, "revoked/"+serial)
+	if err != nil {
+		return nil, err
+	}
+	if raw == nil {
+		return nil, nil
+	}
+
+	var info certRevocationInfo
+	if err := json.Unmarshal(raw.Value, &info); err != nil {
+		return nil, fmt.Errorf("failed decoding revocation entry for serial %s: %w", serial, err)
+	}
+
+	return &info, nil
+}
+
+func malformedOcspResponse() *logical.Response {
+	return ocspFixedResponse(ocsp.MalformedRequestErrorResponse)
+}
+
+func unauthorizedOcspResponse() *logical.Response {
+	return ocspFixedResponse(ocsp.UnauthorizedErrorResponse)
+}
+
+func ocspFixedResponse(body []byte) *logical.Response {
+	return &logical.Response{
+		Data: map[string]interface{}{
+			logical.HTTPStatusCode:  http.StatusOK,
+			logical.HTTPRawBody:     body,
+			logical.HTTPContentType: "application/ocsp-response",
+		},
+	}
+}
+
+const pathOcspHelpSyn = `Answer RFC 6960 OCSP requests for this mount's issuers.`
+
+const pathOcspHelpDesc = `
+This endpoint answers OCSP requests (GET with base64 CertID, or POST with
+an application/ocsp-request body) using both Vault's own revocation store
+and the revoked serials most recently aggregated by an
+issuer/<ref>/resign-crls call for the targeted issuer (a later call for the
+same issuer replaces that set), so that an OCSP responder can be stood up
+for third-party CRLs Vault resigns.
+
+Every response is signed by the targeted issuer itself: this endpoint does
+not verify a request's optional signature (RFC 6960 §4.1.2), and it does
+not support delegating responses to a separate designated OCSP responder
+certificate (an id-kp-OCSPSigning EKU cert, optionally marked
+id-pkix-ocsp-nocheck). Signed requests are accepted but their signature is
+ignored, never rejected for failing to verify.
+`
@@ -0,0 +1,67 @@
+package pki
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+)
+
+func TestOcsp_RevokedViaResignCrls(t *testing.T) {
+	pem1 := "-----BEGIN X509 CRL-----\nMIIBvjCBpwIBATANBgkqhkiG9w0BAQsFADAbMRkwFwYDVQQDExByb290LWV4YW1w\nbGUuY29tFw0yMjEwMjYyMTI5MzlaFw0yMjEwMjkyMTI5MzlaMCcwJQIUSnVf8wsd\nHjOt9drCYFhWxS9QqGoXDTIyMTAyNjIxMjkzOVqgLzAtMB8GA1UdIwQYMBaAFHki\nZ0XDUQVSajNRGXrg66OaIFlYMAoGA1UdFAQDAgEDMA0GCSqGSIb3DQEBCwUAA4IB\nAQBGIdtqTwemnLZF5AoP+jzvKZ26S3y7qvRIzd7f4A0EawzYmWXSXfwqo4TQ4DG3\nnvT+AaA1zCCOlH/1U+ufN9gSSN0j9ax58brSYMnMskMCqhLKIp0qnvS4jr/gopmF\nv8grbvLHEqNYTu1T7umMLdNQUsWT3Qc+EIjfoKj8xD2FHsZwJ+EMbytwl8Unipjr\nhz4rmcES/65vavfdFpOI6YXfi+UAaHBdkTqmHgg4BdpuXfYtlf+iotFSOkygD5fl\n0D+RVFW9uJv2WfbQ7kRt1X/VcFk/onw0AQqxZRVUzvjoMw+EMcxSq3UKOlXcWDxm\nEFz9rFQQ66L388EP8RD7Dh3X\n-----END X509 CRL-----"
+
+	crl1, err := decodePemCrl(pem1)
+	require.NoError(t, err, "failed decoding pem 1 CRL")
+	revokedSerial := crl1.RevokedCertificates[0].SerialNumber
+
+	b, s := createBackendWithStorage(t)
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "root/generate/internal",
+		Storage:   s,
+		Data: map[string]interface{}{
+			"common_name": "test.com",
+		},
+		MountPoint: "pki/",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	pemCaCert := resp.Data["certificate"].(string)
+	caCert := parseCert(t, pemCaCert)
+
+	resp, err = b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "issuer/default/resign-crls",
+		Storage:   s,
+		Data: map[string]interface{}{
+			"crl_number":  "2",
+			"next_update": "1h",
+			"format":      "pem",
+			"crls":        []string{pem1},
+		},
+		MountPoint: "pki/",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+
+	revokedCert := &x509.Certificate{SerialNumber: revokedSerial}
+	ocspReqDer, err := ocsp.CreateRequest(revokedCert, caCert, nil)
+	require.NoError(t, err, "failed building OCSP request")
+
+	resp, err = b.HandleRequest(context.Background(), &logical.Request{
+		Operation:  logical.ReadOperation,
+		Path:       "ocsp/" + base64.StdEncoding.EncodeToString(ocspReqDer),
+		Storage:    s,
+		MountPoint: "pki/",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+
+	ocspResp, err := ocsp.ParseResponse(resp.Data[logical.HTTPRawBody].([]byte), caCert)
+	require.NoError(t, err, "failed parsing OCSP response")
+	require.Equal(t, ocsp.Revoked, ocspResp.Status)
+	require.Equal(t, 0, revokedSerial.Cmp(ocspResp.SerialNumber))
+	require.True(t, ocspResp.RevokedAt.Equal(crl1.RevokedCertificates[0].RevocationTime),
+		"OCSP response must report the CRL entry's actual revocation time, not the time resign-crls ran")
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/asn1"
+	"encoding/pem"
 	"fmt"
 	"math/big"
 	"testing"
@@ -133,6 +134,356 @@ func TestResignCrlsDelta(t *testing.T) {
 	require.NoError(t, err, "failed signature check of CRL")
 }
 
+func TestResignCrlsDeltaWithIdp(t *testing.T) {
+	pem1 := "-----BEGIN X509 CRL-----\nMIIBvjCBpwIBATANBgkqhkiG9w0BAQsFADAbMRkwFwYDVQQDExByb290LWV4YW1w\nbGUuY29tFw0yMjEwMjYyMTI5MzlaFw0yMjEwMjkyMTI5MzlaMCcwJQIUSnVf8wsd\nHjOt9drCYFhWxS9QqGoXDTIyMTAyNjIxMjkzOVqgLzAtMB8GA1UdIwQYMBaAFHki\nZ0XDUQVSajNRGXrg66OaIFlYMAoGA1UdFAQDAgEDMA0GCSqGSIb3DQEBCwUAA4IB\nAQBGIdtqTwemnLZF5AoP+jzvKZ26S3y7qvRIzd7f4A0EawzYmWXSXfwqo4TQ4DG3\nnvT+AaA1zCCOlH/1U+ufN9gSSN0j9ax58brSYMnMskMCqhLKIp0qnvS4jr/gopmF\nv8grbvLHEqNYTu1T7umMLdNQUsWT3Qc+EIjfoKj8xD2FHsZwJ+EMbytwl8Unipjr\nhz4rmcES/65vavfdFpOI6YXfi+UAaHBdkTqmHgg4BdpuXfYtlf+iotFSOkygD5fl\n0D+RVFW9uJv2WfbQ7kRt1X/VcFk/onw0AQqxZRVUzvjoMw+EMcxSq3UKOlXcWDxm\nEFz9rFQQ66L388EP8RD7Dh3X\n-----END X509 CRL-----"
+
+	b, s := createBackendWithStorage(t)
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "root/generate/internal",
+		Storage:   s,
+		Data: map[string]interface{}{
+			"common_name": "test.com",
+		},
+		MountPoint: "pki/",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	pemCaCert := resp.Data["certificate"].(string)
+	caCert := parseCert(t, pemCaCert)
+
+	resp, err = b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "issuer/default/resign-crls",
+		Storage:   s,
+		Data: map[string]interface{}{
+			"crl_number":                  "5",
+			"base_crl_number":             "4",
+			"next_update":                 "12h",
+			"format":                      "pem",
+			"crls":                        []string{pem1},
+			"idp_only_user_certs":         true,
+			"idp_distribution_point_urls": "http://example.com/crl",
+		},
+		MountPoint: "pki/",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	requireFieldsSetInResp(t, resp, "crl")
+	pemCrl := resp.Data["crl"].(string)
+	combinedCrl, err := decodePemCrl(pemCrl)
+	require.NoError(t, err, "failed decoding combined CRL")
+
+	extensions := combinedCrl.Extensions
+	requireExtensionOid(t, []int{2, 5, 29, 27}, extensions) // Delta CRL Indicator
+	requireExtensionOid(t, []int{2, 5, 29, 20}, extensions) // CRL Number Extension
+	requireExtensionOid(t, []int{2, 5, 29, 35}, extensions) // akidOid
+	requireExtensionOid(t, []int{2, 5, 29, 28}, extensions) // Issuing Distribution Point
+	require.Equal(t, 4, len(extensions))
+
+	var baseNumber int
+	for _, ext := range extensions {
+		if ext.Id.Equal(asn1.ObjectIdentifier{2, 5, 29, 27}) {
+			_, err := asn1.Unmarshal(ext.Value, &baseNumber)
+			require.NoError(t, err, "failed decoding Delta CRL Indicator value")
+		}
+	}
+	require.Equal(t, 4, baseNumber)
+
+	err = combinedCrl.CheckSignatureFrom(caCert)
+	require.NoError(t, err, "failed signature check of CRL")
+}
+
+func TestResignCrlsPkcs7(t *testing.T) {
+	pem1 := "-----BEGIN X509 CRL-----\nMIIBvjCBpwIBATANBgkqhkiG9w0BAQsFADAbMRkwFwYDVQQDExByb290LWV4YW1w\nbGUuY29tFw0yMjEwMjYyMTI5MzlaFw0yMjEwMjkyMTI5MzlaMCcwJQIUSnVf8wsd\nHjOt9drCYFhWxS9QqGoXDTIyMTAyNjIxMjkzOVqgLzAtMB8GA1UdIwQYMBaAFHki\nZ0XDUQVSajNRGXrg66OaIFlYMAoGA1UdFAQDAgEDMA0GCSqGSIb3DQEBCwUAA4IB\nAQBGIdtqTwemnLZF5AoP+jzvKZ26S3y7qvRIzd7f4A0EawzYmWXSXfwqo4TQ4DG3\nnvT+AaA1zCCOlH/1U+ufN9gSSN0j9ax58brSYMnMskMCqhLKIp0qnvS4jr/gopmF\nv8grbvLHEqNYTu1T7umMLdNQUsWT3Qc+EIjfoKj8xD2FHsZwJ+EMbytwl8Unipjr\nhz4rmcES/65vavfdFpOI6YXfi+UAaHBdkTqmHgg4BdpuXfYtlf+iotFSOkygD5fl\n0D+RVFW9uJv2WfbQ7kRt1X/VcFk/onw0AQqxZRVUzvjoMw+EMcxSq3UKOlXcWDxm\nEFz9rFQQ66L388EP8RD7Dh3X\n-----END X509 CRL-----"
+	pem2 := "-----BEGIN X509 CRL-----\nMIIBvjCBpwIBATANBgkqhkiG9w0BAQsFADAbMRkwFwYDVQQDExByb290LWV4YW1w\nbGUuY29tFw0yMjEwMjYyMTI5MzlaFw0yMjEwMjkyMTI5MzlaMCcwJQIUPPlHdKzc\nnMljHN3vDcqQkyRWWxQXDTIyMTAyNjIxMjkzOVqgLzAtMB8GA1UdIwQYMBaAFMbF\nfDMrtoqudv3bp1YLbjNOqY/YMAoGA1UdFAQDAgEDMA0GCSqGSIb3DQEBCwUAA4IB\nAQBYBeMjyffefICs2nNy6Fs0SsKyWCk1IS5tu49hEOnxck9UTllu7nktVLis5+5p\nM51FDhFp7L+Su67nMLYgqs6+9CV2QiacGul6kW/ubVIGu5uaNo3duYUrF6tLre/m\nkftUo4yzSF3buB4xu+5lZktgLvh/icofzoa2QwMJNKdApqVxDXbr8HQtM6eep4i1\n+KbFrficULHqDC5XBIT140NzzPsIYWFjtjSB/bTTxScNOma776CdQK4I+CzPF6++\nhxiyNaN7qqkrP+4w9XWAg7CorakPfY00oDcnCiqF70qdZm8VcvpUpf2HqdSGQrwI\nkw0UTVewb0wSok+H4TCWep2L\n-----END X509 CRL-----"
+
+	crl1, err := decodePemCrl(pem1)
+	require.NoError(t, err, "failed decoding pem 1 CRL")
+	crl2, err := decodePemCrl(pem2)
+	require.NoError(t, err, "failed decoding pem 2 CRL")
+
+	pem1Serial := extractSerialsFromCrl(crl1)[0]
+	pem2Serial := extractSerialsFromCrl(crl2)[0]
+
+	p7Der, err := encodeCrlAsPkcs7(crl2.Raw)
+	require.NoError(t, err, "failed wrapping pem 2 CRL in PKCS#7")
+	p7Pem := string(pem.EncodeToMemory(&pem.Block{Type: "PKCS7", Bytes: p7Der}))
+
+	b, s := createBackendWithStorage(t)
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "root/generate/internal",
+		Storage:   s,
+		Data: map[string]interface{}{
+			"common_name": "test.com",
+		},
+		MountPoint: "pki/",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	pemCaCert := resp.Data["certificate"].(string)
+	caCert := parseCert(t, pemCaCert)
+
+	resp, err = b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "issuer/default/resign-crls",
+		Storage:   s,
+		Data: map[string]interface{}{
+			"crl_number":  "2",
+			"next_update": "1h",
+			"format":      "pkcs7-pem",
+			"crls":        []string{pem1, p7Pem},
+		},
+		MountPoint: "pki/",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	requireFieldsSetInResp(t, resp, "crl")
+	pemP7 := resp.Data["crl"].(string)
+
+	block, _ := pem.Decode([]byte(pemP7))
+	require.NotNil(t, block, "failed decoding PKCS#7 PEM armor")
+	require.Equal(t, "PKCS7", block.Type)
+
+	crls, err := decodePkcs7Crls(block.Bytes)
+	require.NoError(t, err, "failed unwrapping resulting PKCS#7 envelope")
+	require.Equal(t, 1, len(crls))
+	combinedCrl := crls[0]
+	serials := extractSerialsFromCrl(combinedCrl)
+
+	require.Contains(t, serials, pem1Serial)
+	require.Contains(t, serials, pem2Serial)
+	require.Equal(t, 2, len(serials), "serials contained more serials than expected")
+
+	err = combinedCrl.CheckSignatureFrom(caCert)
+	require.NoError(t, err, "failed signature check of CRL")
+}
+
+// TestResignCrlsPkcs7WithCertificates covers a PKCS#7/CMS SignedData
+// envelope that also carries the signer's certificate(s) in the optional
+// certificates [0] field, the shape NDES/Cisco-style transports normally
+// produce, as opposed to the degenerate encodeCrlAsPkcs7 output (no
+// certificates, no signer infos) exercised by TestResignCrlsPkcs7.
+func TestResignCrlsPkcs7WithCertificates(t *testing.T) {
+	pem1 := "-----BEGIN X509 CRL-----\nMIIBvjCBpwIBATANBgkqhkiG9w0BAQsFADAbMRkwFwYDVQQDExByb290LWV4YW1w\nbGUuY29tFw0yMjEwMjYyMTI5MzlaFw0yMjEwMjkyMTI5MzlaMCcwJQIUSnVf8wsd\nHjOt9drCYFhWxS9QqGoXDTIyMTAyNjIxMjkzOVqgLzAtMB8GA1UdIwQYMBaAFHki\nZ0XDUQVSajNRGXrg66OaIFlYMAoGA1UdFAQDAgEDMA0GCSqGSIb3DQEBCwUAA4IB\nAQBGIdtqTwemnLZF5AoP+jzvKZ26S3y7qvRIzd7f4A0EawzYmWXSXfwqo4TQ4DG3\nnvT+AaA1zCCOlH/1U+ufN9gSSN0j9ax58brSYMnMskMCqhLKIp0qnvS4jr/gopmF\nv8grbvLHEqNYTu1T7umMLdNQUsWT3Qc+EIjfoKj8xD2FHsZwJ+EMbytwl8Unipjr\nhz4rmcES/65vavfdFpOI6YXfi+UAaHBdkTqmHgg4BdpuXfYtlf+iotFSOkygD5fl\n0D+RVFW9uJv2WfbQ7kRt1X/VcFk/onw0AQqxZRVUzvjoMw+EMcxSq3UKOlXcWDxm\nEFz9rFQQ66L388EP8RD7Dh3X\n-----END X509 CRL-----"
+
+	crl1, err := decodePemCrl(pem1)
+	require.NoError(t, err, "failed decoding pem 1 CRL")
+	pem1Serial := extractSerialsFromCrl(crl1)[0]
+
+	sd := cmsSignedData{
+		Version:          1,
+		DigestAlgorithms: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true},
+		ContentInfo:      cmsEncapsulatedContentInfo{ContentType: oidData},
+		Certificates:     []asn1.RawValue{{FullBytes: crl1.Raw}}, // placeholder DER; decode must not require valid certs
+		Crls:             []asn1.RawValue{{FullBytes: crl1.Raw}},
+		SignerInfos:      asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true},
+	}
+	sdBytes, err := asn1.Marshal(sd)
+	require.NoError(t, err, "failed marshaling SignedData with a populated certificates field")
+
+	outer := cmsContentInfo{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: sdBytes},
+	}
+	der, err := asn1.Marshal(outer)
+	require.NoError(t, err, "failed marshaling ContentInfo")
+
+	crls, err := decodePkcs7Crls(der)
+	require.NoError(t, err, "failed unwrapping PKCS#7 envelope carrying a certificates [0] field")
+	require.Equal(t, 1, len(crls))
+	require.Contains(t, extractSerialsFromCrl(crls[0]), pem1Serial)
+}
+
+func TestResignCrlsIndirect(t *testing.T) {
+	// pem1 and pem2 are issued by two distinct CAs (root-example-a.com,
+	// root-example-b.com) with distinct revoked serials, so this proves
+	// certificateIssuer entries attribute each carried-forward revocation
+	// to the correct source CRL rather than just whichever issuer happens
+	// to be first.
+	pem1 := "-----BEGIN X509 CRL-----\nMIHkMIGUAgEBMAoGCCqGSM49BAMCMB0xGzAZBgNVBAMTEnJvb3QtZXhhbXBsZS1h\nLmNvbRcNMjIxMDI2MjEyOTM5WhcNMjIxMDI5MjEyOTM5WjAVMBMCAgPoFw0yMjEw\nMjYyMTI5MzlaoC8wLTAfBgNVHSMEGDAWgBRRraquLKZmtSaM/SS1QKvgZwJQcDAK\nBgNVHRQEAwIBAzAKBggqhkjOPQQDAgM/ADA8Ahx/6i2L6Ol1nY9yFCR4YKleVbpD\n1nrnqIeB7Pa/AhwBUZp8M50gZvdLnMUx0GMBPUmrtP3V8Bubb0jU\n-----END X509 CRL-----"
+	pem2 := "-----BEGIN X509 CRL-----\nMIHlMIGUAgEBMAoGCCqGSM49BAMCMB0xGzAZBgNVBAMTEnJvb3QtZXhhbXBsZS1i\nLmNvbRcNMjIxMDI2MjEyOTM5WhcNMjIxMDI5MjEyOTM5WjAVMBMCAgPpFw0yMjEw\nMjYyMTI5MzlaoC8wLTAfBgNVHSMEGDAWgBTb8DLBZnN7DEWMnm5VHkbSxdMP9TAK\nBgNVHRQEAwIBAzAKBggqhkjOPQQDAgNAADA9Ah0A07d0xWK4qQr/AS5OF/Dm9pI7\nQlSiQ0jBmt7SGwIcR0ntGi+C1CO6mTrRFAeAysH9y1LsECJZQc9UeQ==\n-----END X509 CRL-----"
+
+	crl1, err := decodePemCrl(pem1)
+	require.NoError(t, err, "failed decoding pem 1 CRL")
+	crl2, err := decodePemCrl(pem2)
+	require.NoError(t, err, "failed decoding pem 2 CRL")
+
+	pem1Serial := extractSerialsFromCrl(crl1)[0]
+	pem2Serial := extractSerialsFromCrl(crl2)[0]
+
+	b, s := createBackendWithStorage(t)
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "root/generate/internal",
+		Storage:   s,
+		Data: map[string]interface{}{
+			"common_name": "test.com",
+		},
+		MountPoint: "pki/",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	pemCaCert := resp.Data["certificate"].(string)
+	caCert := parseCert(t, pemCaCert)
+
+	resp, err = b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "issuer/default/resign-crls",
+		Storage:   s,
+		Data: map[string]interface{}{
+			"crl_number":   "2",
+			"next_update":  "1h",
+			"format":       "pem",
+			"crls":         []string{pem1, pem2},
+			"indirect_crl": true,
+		},
+		MountPoint: "pki/",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	requireFieldsSetInResp(t, resp, "crl")
+	pemCrl := resp.Data["crl"].(string)
+	combinedCrl, err := decodePemCrl(pemCrl)
+	require.NoError(t, err, "failed decoding combined CRL")
+
+	requireExtensionOid(t, []int{2, 5, 29, 28}, combinedCrl.Extensions) // Issuing Distribution Point
+	requireIdpIndirectCrl(t, combinedCrl.Extensions)
+
+	bySerial := make(map[string]pkix.RevokedCertificate)
+	for _, revoked := range combinedCrl.RevokedCertificates {
+		bySerial[serialFromBigInt(revoked.SerialNumber)] = revoked
+	}
+
+	expectedIssuer := map[string]string{
+		pem1Serial: crl1.Issuer.String(),
+		pem2Serial: crl2.Issuer.String(),
+	}
+	for serial, issuer := range expectedIssuer {
+		revoked, ok := bySerial[serial]
+		require.True(t, ok, "missing revoked entry for serial %s", serial)
+		requireExtensionOid(t, []int{2, 5, 29, 29}, revoked.Extensions, "missing certificateIssuer extension for serial %s", serial)
+
+		var ext pkix.Extension
+		for _, e := range revoked.Extensions {
+			if e.Id.Equal(asn1.ObjectIdentifier{2, 5, 29, 29}) {
+				ext = e
+			}
+		}
+		require.True(t, ext.Critical, "certificateIssuer extension for serial %s must be critical", serial)
+		require.Equal(t, issuer, decodeCertificateIssuerName(t, ext).String())
+	}
+
+	err = combinedCrl.CheckSignatureFrom(caCert)
+	require.NoError(t, err, "failed signature check of CRL")
+}
+
+// TestResignCrlsIndirectDedupAcrossIssuers covers two CRLs from distinct
+// issuers that each revoke the same serial number: with indirect_crl set,
+// dedup must key on (issuer, serial), not serial alone, or one issuer's
+// legitimate revocation would be silently dropped.
+func TestResignCrlsIndirectDedupAcrossIssuers(t *testing.T) {
+	pemA := "-----BEGIN X509 CRL-----\nMIHmMIGUAgEBMAoGCCqGSM49BAMCMB0xGzAZBgNVBAMTEnJvb3QtZXhhbXBsZS1h\nLmNvbRcNMjIxMDI2MjEyOTM5WhcNMjIxMDI5MjEyOTM5WjAVMBMCAiMoFw0yMjEw\nMjYyMTI5MzlaoC8wLTAfBgNVHSMEGDAWgBSxW/u4cdN3FV0PzLGY9f+8NbCgnTAK\nBgNVHRQEAwIBAzAKBggqhkjOPQQDAgNBADA+Ah0A7A+WuAIcbSOAaKTSPPtqU18f\nAPJt8pJOv7qZuQIdANl5cyT6vmVv4XVwbgGcVYx8tgMuZ3pHGL/Yb80=\n-----END X509 CRL-----"
+	pemB := "-----BEGIN X509 CRL-----\nMIHlMIGUAgEBMAoGCCqGSM49BAMCMB0xGzAZBgNVBAMTEnJvb3QtZXhhbXBsZS1i\nLmNvbRcNMjIxMDI2MjEyOTM5WhcNMjIxMDI5MjEyOTM5WjAVMBMCAiMoFw0yMjEw\nMjYyMTI5MzlaoC8wLTAfBgNVHSMEGDAWgBTBshKcDMDUU4n2HfxMYvSWKjE7kjAK\nBgNVHRQEAwIBAzAKBggqhkjOPQQDAgNAADA9Ahx9KAWmwjKb4/prQuN5B8Nvobru\nW2B8Xcmv/ypVAh0Anqs+injs9S92O4iROz+KItbNDZSA9tII7RpXlQ==\n-----END X509 CRL-----"
+
+	crlA, err := decodePemCrl(pemA)
+	require.NoError(t, err, "failed decoding pem A CRL")
+	crlB, err := decodePemCrl(pemB)
+	require.NoError(t, err, "failed decoding pem B CRL")
+	require.Equal(t, crlA.RevokedCertificates[0].SerialNumber, crlB.RevokedCertificates[0].SerialNumber,
+		"fixture requires both CRLs to revoke the same serial under different issuers")
+
+	b, s := createBackendWithStorage(t)
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "root/generate/internal",
+		Storage:   s,
+		Data: map[string]interface{}{
+			"common_name": "test.com",
+		},
+		MountPoint: "pki/",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+
+	resp, err = b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "issuer/default/resign-crls",
+		Storage:   s,
+		Data: map[string]interface{}{
+			"crl_number":   "2",
+			"next_update":  "1h",
+			"format":       "pem",
+			"crls":         []string{pemA, pemB},
+			"indirect_crl": true,
+		},
+		MountPoint: "pki/",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	requireFieldsSetInResp(t, resp, "crl")
+	pemCrl := resp.Data["crl"].(string)
+	combinedCrl, err := decodePemCrl(pemCrl)
+	require.NoError(t, err, "failed decoding combined CRL")
+
+	require.Equal(t, 2, len(combinedCrl.RevokedCertificates),
+		"both issuers' revocations of the shared serial must be kept, not deduped away")
+
+	var sawIssuerA, sawIssuerB bool
+	for _, revoked := range combinedCrl.RevokedCertificates {
+		var ext pkix.Extension
+		for _, e := range revoked.Extensions {
+			if e.Id.Equal(asn1.ObjectIdentifier{2, 5, 29, 29}) {
+				ext = e
+			}
+		}
+		switch decodeCertificateIssuerName(t, ext).String() {
+		case crlA.Issuer.String():
+			sawIssuerA = true
+		case crlB.Issuer.String():
+			sawIssuerB = true
+		}
+	}
+	require.True(t, sawIssuerA, "missing carried-forward revocation attributed to issuer A")
+	require.True(t, sawIssuerB, "missing carried-forward revocation attributed to issuer B")
+}
+
+// decodeCertificateIssuerName parses a certificateIssuer entry extension's
+// GeneralNames value back into the directoryName it carries.
+func decodeCertificateIssuerName(t *testing.T, ext pkix.Extension) pkix.Name {
+	var generalNames []asn1.RawValue
+	_, err := asn1.Unmarshal(ext.Value, &generalNames)
+	require.NoError(t, err, "failed decoding certificateIssuer GeneralNames")
+	require.Equal(t, 1, len(generalNames))
+
+	var rdn pkix.RDNSequence
+	_, err = asn1.Unmarshal(generalNames[0].Bytes, &rdn)
+	require.NoError(t, err, "failed decoding certificateIssuer directoryName")
+
+	var name pkix.Name
+	name.FillFromRDNSequence(&rdn)
+	return name
+}
+
+// requireIdpIndirectCrl asserts the Issuing Distribution Point extension's
+// indirectCRL boolean (implicit tag 4) is present and true.
+func requireIdpIndirectCrl(t *testing.T, extensions []pkix.Extension) {
+	for _, ext := range extensions {
+		if !ext.Id.Equal(asn1.ObjectIdentifier{2, 5, 29, 28}) {
+			continue
+		}
+
+		var fields []asn1.RawValue
+		_, err := asn1.Unmarshal(ext.Value, &fields)
+		require.NoError(t, err, "failed decoding Issuing Distribution Point")
+
+		for _, field := range fields {
+			if field.Tag == 4 {
+				var indirect bool
+				_, err := asn1.UnmarshalWithParams(field.FullBytes, &indirect, "tag:4")
+				require.NoError(t, err, "failed decoding indirectCRL boolean")
+				require.True(t, indirect)
+				return
+			}
+		}
+		require.Fail(t, "Issuing Distribution Point extension did not contain an indirectCRL field")
+	}
+	require.Fail(t, "missing Issuing Distribution Point extension")
+}
+
 func requireExtensionOid(t *testing.T, identifier asn1.ObjectIdentifier, extensions []pkix.Extension, msgAndArgs ...interface{}) {
 	found := false
 	var oidsInExtensions []string